@@ -0,0 +1,76 @@
+// Package convert synthesizes a pawnpackage.Package from a project that does
+// not yet have a pawn.json/pawn.yaml, by recognising the layout of a handful
+// of foreign or legacy manifest conventions.
+package convert
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/sampctl/pawnpackage"
+)
+
+// Importer detects and translates one foreign project layout into a Package.
+type Importer interface {
+	// Name is the identifier used with `--from`, eg. "pawncmd".
+	Name() string
+	// Detect reports whether dir looks like this importer's project layout.
+	Detect(dir string) bool
+	// Import reads dir and produces an equivalent Package.
+	Import(ctx context.Context, client *github.Client, dir string) (pkg pawnpackage.Package, err error)
+}
+
+var importers = []Importer{
+	pawnCMDImporter{},
+	quickbuildImporter{},
+	legacyImporter{},
+	plainImporter{},
+}
+
+// ByName returns the registered importer with the given name.
+func ByName(name string) (imp Importer, ok bool) {
+	for _, i := range importers {
+		if i.Name() == name {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+// Convert synthesizes a Package from dir using the importer named by from. If
+// from is "auto", every registered importer is tried in order and the first
+// one that detects a matching layout is used.
+func Convert(ctx context.Context, client *github.Client, dir, from string) (pkg pawnpackage.Package, err error) {
+	if from == "" || from == "auto" {
+		for _, imp := range importers {
+			if imp.Detect(dir) {
+				return imp.Import(ctx, client, dir)
+			}
+		}
+		return pkg, errors.Errorf("no known project layout detected in '%s'", dir)
+	}
+
+	imp, ok := ByName(from)
+	if !ok {
+		return pkg, errors.Errorf("unknown importer '%s'", from)
+	}
+	if !imp.Detect(dir) {
+		return pkg, errors.Errorf("'%s' does not look like a %s project", dir, from)
+	}
+	return imp.Import(ctx, client, dir)
+}
+
+// stubDependencyVersion queries GitHub for the newest semver tag of a
+// referenced library when a foreign manifest names a dependency without
+// pinning a version, so the generated pawn.yaml is still reproducible. This
+// reuses pawnpackage.LatestSemverTag rather than trusting the tags API's
+// response order, which GitHub does not guarantee to be recency-sorted.
+func stubDependencyVersion(ctx context.Context, client *github.Client, user, repo string) (tag string, err error) {
+	tag, err = pawnpackage.LatestSemverTag(ctx, client, user, repo)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find latest tag for '%s/%s'", user, repo)
+	}
+	return tag, nil
+}
@@ -0,0 +1,78 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/sampctl/pawnpackage"
+	"github.com/Southclaws/sampctl/util"
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+// legacyImporter converts the `packages.json` format used by early,
+// pre-pawn.json versions of sampctl, which stored dependencies as a flat list
+// of "user/repo" strings with no version pinning at all.
+type legacyImporter struct{}
+
+func (legacyImporter) Name() string { return "sampctl-legacy" }
+
+func (legacyImporter) Detect(dir string) bool {
+	return util.Exists(filepath.Join(dir, "packages.json"))
+}
+
+type legacyManifest struct {
+	Entry        string   `json:"entry"`
+	Output       string   `json:"output"`
+	Dependencies []string `json:"dependencies"`
+}
+
+func (legacyImporter) Import(ctx context.Context, client *github.Client, dir string) (pkg pawnpackage.Package, err error) {
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "packages.json"))
+	if err != nil {
+		return pkg, errors.Wrap(err, "failed to read packages.json")
+	}
+
+	var manifest legacyManifest
+	if err = json.Unmarshal(contents, &manifest); err != nil {
+		return pkg, errors.Wrap(err, "failed to decode packages.json")
+	}
+
+	pkg.Format = "yaml"
+	pkg.LocalPath = dir
+	pkg.Entry = manifest.Entry
+	pkg.Output = manifest.Output
+
+	for _, dep := range manifest.Dependencies {
+		user, repo, ok := splitUserRepo(dep)
+		if !ok {
+			continue
+		}
+
+		tag, errStub := stubDependencyVersion(ctx, client, user, repo)
+		if errStub != nil {
+			return pkg, errStub
+		}
+
+		depString := dep
+		if tag != "" {
+			depString = dep + "@" + tag
+		}
+		pkg.Dependencies = append(pkg.Dependencies, versioning.DependencyString(depString))
+	}
+
+	return pkg, nil
+}
+
+func splitUserRepo(dep string) (user, repo string, ok bool) {
+	parts := strings.SplitN(dep, "/", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,73 @@
+package convert
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/sampctl/build"
+	"github.com/Southclaws/sampctl/pawnpackage"
+	"github.com/Southclaws/sampctl/util"
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+// pawnCMDImporter converts a Pawn.CMD project file, a simple line-based batch
+// script historically used to invoke the compiler directly, into a Package.
+type pawnCMDImporter struct{}
+
+func (pawnCMDImporter) Name() string { return "pawncmd" }
+
+func (pawnCMDImporter) Detect(dir string) bool {
+	return util.Exists(filepath.Join(dir, "Pawn.CMD"))
+}
+
+// pawnCMDIncludeRe matches -i"path" / -ipath include flags in a Pawn.CMD. The
+// path must follow -i directly; "-i path" with a space between is not matched.
+var pawnCMDIncludeRe = regexp.MustCompile(`-i"?([^"\s]+)"?`)
+
+// pawnCMDEntryRe matches the .pwn source file passed to the compiler.
+var pawnCMDEntryRe = regexp.MustCompile(`([\w./\\-]+\.pwn)`)
+
+func (pawnCMDImporter) Import(ctx context.Context, client *github.Client, dir string) (pkg pawnpackage.Package, err error) {
+	f, err := os.Open(filepath.Join(dir, "Pawn.CMD"))
+	if err != nil {
+		return pkg, errors.Wrap(err, "failed to open Pawn.CMD")
+	}
+	defer f.Close() // nolint:errcheck
+
+	pkg.Format = "yaml"
+	pkg.LocalPath = dir
+	cfg := build.Default()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := pawnCMDEntryRe.FindStringSubmatch(line); m != nil && pkg.Entry == "" {
+			pkg.Entry = filepath.ToSlash(m[1])
+			pkg.Output = pkg.Entry[:len(pkg.Entry)-len(filepath.Ext(pkg.Entry))] + ".amx"
+		}
+
+		for _, m := range pawnCMDIncludeRe.FindAllStringSubmatch(line, -1) {
+			include := filepath.ToSlash(m[1])
+			if pkg.IncludePath == "" {
+				pkg.IncludePath = include
+			} else {
+				cfg.Args = append(cfg.Args, "-i"+include)
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return pkg, errors.Wrap(err, "failed to scan Pawn.CMD")
+	}
+
+	pkg.Build = cfg
+	pkg.Dependencies = []versioning.DependencyString{}
+
+	return pkg, nil
+}
@@ -0,0 +1,44 @@
+package convert
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/google/go-github/github"
+
+	"github.com/Southclaws/sampctl/pawnpackage"
+	"github.com/Southclaws/sampctl/util"
+)
+
+// plainImporter converts a directory with no manifest at all, just plain
+// `gamemodes/` and `include/` folders, inferring the entry point as the first
+// `.pwn` file found in `gamemodes/`. This is the fallback importer tried last
+// in `--from auto` mode since every other layout is more specific.
+type plainImporter struct{}
+
+func (plainImporter) Name() string { return "plain" }
+
+func (plainImporter) Detect(dir string) bool {
+	return util.Exists(filepath.Join(dir, "gamemodes")) || util.Exists(filepath.Join(dir, "include"))
+}
+
+func (plainImporter) Import(ctx context.Context, client *github.Client, dir string) (pkg pawnpackage.Package, err error) {
+	pkg.Format = "yaml"
+	pkg.LocalPath = dir
+
+	if util.Exists(filepath.Join(dir, "include")) {
+		pkg.IncludePath = "include"
+	}
+
+	gamemodesDir := filepath.Join(dir, "gamemodes")
+	if util.Exists(gamemodesDir) {
+		matches, errGlob := filepath.Glob(filepath.Join(gamemodesDir, "*.pwn"))
+		if errGlob == nil && len(matches) > 0 {
+			rel, _ := filepath.Rel(dir, matches[0])
+			pkg.Entry = filepath.ToSlash(rel)
+			pkg.Output = pkg.Entry[:len(pkg.Entry)-len(filepath.Ext(pkg.Entry))] + ".amx"
+		}
+	}
+
+	return pkg, nil
+}
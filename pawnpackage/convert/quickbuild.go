@@ -0,0 +1,71 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/sampctl/build"
+	"github.com/Southclaws/sampctl/pawnpackage"
+)
+
+// quickbuildImporter converts a Sublime Text `.sublime-project` that uses the
+// popular "Pawn Quick Build" package's compile settings into a Package. These
+// projects store their compiler flags under `settings.sublimeqb_build_flags`
+// and their include paths under `settings.sublimeqb_include_paths`.
+type quickbuildImporter struct{}
+
+func (quickbuildImporter) Name() string { return "quickbuild" }
+
+func (quickbuildImporter) Detect(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.sublime-project"))
+	return len(matches) > 0
+}
+
+type sublimeProject struct {
+	Settings struct {
+		QuickBuildEntry        string   `json:"sublimeqb_input_file"`
+		QuickBuildIncludePaths []string `json:"sublimeqb_include_paths"`
+		QuickBuildFlags        []string `json:"sublimeqb_build_flags"`
+	} `json:"settings"`
+}
+
+func (quickbuildImporter) Import(ctx context.Context, client *github.Client, dir string) (pkg pawnpackage.Package, err error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.sublime-project"))
+	if err != nil {
+		return pkg, errors.Wrap(err, "failed to glob for .sublime-project")
+	}
+	if len(matches) == 0 {
+		return pkg, errors.New("no .sublime-project file found")
+	}
+
+	contents, err := ioutil.ReadFile(matches[0])
+	if err != nil {
+		return pkg, errors.Wrapf(err, "failed to read '%s'", matches[0])
+	}
+
+	var proj sublimeProject
+	if err = json.Unmarshal(contents, &proj); err != nil {
+		return pkg, errors.Wrapf(err, "failed to decode '%s'", matches[0])
+	}
+
+	pkg.Format = "yaml"
+	pkg.LocalPath = dir
+	pkg.Entry = filepath.ToSlash(proj.Settings.QuickBuildEntry)
+	if pkg.Entry != "" {
+		pkg.Output = pkg.Entry[:len(pkg.Entry)-len(filepath.Ext(pkg.Entry))] + ".amx"
+	}
+	if len(proj.Settings.QuickBuildIncludePaths) > 0 {
+		pkg.IncludePath = filepath.ToSlash(proj.Settings.QuickBuildIncludePaths[0])
+	}
+
+	cfg := build.Default()
+	cfg.Args = append(cfg.Args, proj.Settings.QuickBuildFlags...)
+	pkg.Build = cfg
+
+	return pkg, nil
+}
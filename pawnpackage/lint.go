@@ -0,0 +1,247 @@
+package pawnpackage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Southclaws/sampctl/build"
+	"github.com/Southclaws/sampctl/run"
+	"github.com/Southclaws/sampctl/util"
+)
+
+// Severity classifies how serious a Diagnostic is. Editors and CI can use
+// this to decide whether to fail a build or merely surface a warning.
+type Severity string
+
+// Diagnostic severities.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Diagnostic is a single linter finding against a package definition, with
+// a rule id and message precise enough for CI to fail on. The rules in this
+// file set File but leave Line and Column zero, so a diagnostic names the
+// definition file and not the offending field's position within it.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", d.File, d.Line, d.Column, d.Severity, d.Message, d.Rule)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", d.File, d.Severity, d.Message, d.Rule)
+}
+
+// Lint runs every linter rule against the package and returns the full list
+// of diagnostics found, in contrast to Validate which only checks for the
+// handful of conditions that make a package entirely unusable.
+func (pkg Package) Lint(ctx context.Context) (diagnostics []Diagnostic) {
+	file := pkg.definitionFile()
+
+	diagnostics = append(diagnostics, lintDuplicateDependencies(pkg, file)...)
+	diagnostics = append(diagnostics, lintUnpinnedDependencies(pkg, file)...)
+	diagnostics = append(diagnostics, lintEntryOutputCollision(pkg, file)...)
+	diagnostics = append(diagnostics, lintIncludePaths(pkg, file)...)
+	diagnostics = append(diagnostics, lintCompilerVersions(pkg, file)...)
+	diagnostics = append(diagnostics, lintResourceCollisions(pkg, file)...)
+	diagnostics = append(diagnostics, lintMissingPlugins(pkg, file)...)
+
+	return diagnostics
+}
+
+// definitionFile returns the filename of the package's pawn.json/pawn.yaml,
+// used to populate Diagnostic.File.
+func (pkg Package) definitionFile() string {
+	if pkg.Format == "" {
+		return "pawn.json"
+	}
+	return "pawn." + pkg.Format
+}
+
+func lintDuplicateDependencies(pkg Package, file string) (diagnostics []Diagnostic) {
+	seen := map[string]bool{}
+	for _, dep := range pkg.GetAllDependencies() {
+		meta, err := dep.Explode()
+		if err != nil {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", meta.User, meta.Repo)
+		if seen[key] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityError,
+				File:     file,
+				Rule:     "duplicate-dependency",
+				Message:  fmt.Sprintf("dependency '%s' is declared more than once", key),
+			})
+		}
+		seen[key] = true
+	}
+	return
+}
+
+func lintUnpinnedDependencies(pkg Package, file string) (diagnostics []Diagnostic) {
+	for _, dep := range pkg.GetAllDependencies() {
+		meta, err := dep.Explode()
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityError,
+				File:     file,
+				Rule:     "malformed-dependency",
+				Message:  fmt.Sprintf("dependency '%s' could not be parsed: %s", dep, err),
+			})
+			continue
+		}
+		if meta.Tag == "" && meta.Branch == "" && meta.Commit == "" {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				File:     file,
+				Rule:     "unpinned-dependency",
+				Message:  fmt.Sprintf("dependency '%s/%s' has no tag, branch or commit pinned", meta.User, meta.Repo),
+			})
+		}
+	}
+	return
+}
+
+func lintEntryOutputCollision(pkg Package, file string) (diagnostics []Diagnostic) {
+	if pkg.Entry != "" && pkg.Entry == pkg.Output {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityError,
+			File:     file,
+			Rule:     "entry-output-collision",
+			Message:  "entry and output point to the same file",
+		})
+	}
+	return
+}
+
+func lintIncludePaths(pkg Package, file string) (diagnostics []Diagnostic) {
+	if pkg.IncludePath == "" || pkg.LocalPath == "" {
+		return
+	}
+	full := filepath.Join(pkg.LocalPath, pkg.IncludePath)
+	if !util.Exists(full) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityError,
+			File:     file,
+			Rule:     "unreachable-include-path",
+			Message:  fmt.Sprintf("include_path '%s' does not exist on disk", pkg.IncludePath),
+		})
+	}
+	return
+}
+
+// knownCompilerVersions is the set of compiler versions sampctl knows how to
+// download, kept in sync with the versions shipped by community-pawn/compiler
+// releases.
+var knownCompilerVersions = map[string]bool{
+	"3.10.10":  true,
+	"3.10.9":   true,
+	"3.10.8":   true,
+	"3.10.7":   true,
+	"3.10.6":   true,
+	"3.10.5":   true,
+	"3.10.4":   true,
+	"3.10.3":   true,
+	"3.10.2":   true,
+	"3.10.1":   true,
+	"3.10.0":   true,
+	"3.2.3664": true,
+}
+
+func lintCompilerVersions(pkg Package, file string) (diagnostics []Diagnostic) {
+	// Copy into a fresh slice rather than appending onto pkg.Builds directly:
+	// pkg is passed by value but a slice field still shares its backing array
+	// with the caller, so appending in place can silently overwrite the
+	// caller's data whenever cap(pkg.Builds) > len(pkg.Builds).
+	configs := append([]*build.Config(nil), pkg.Builds...)
+	if pkg.Build != nil {
+		configs = append(configs, pkg.Build)
+	}
+	for _, cfg := range configs {
+		if cfg == nil || cfg.Version == "" {
+			continue
+		}
+		if !knownCompilerVersions[string(cfg.Version)] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				File:     file,
+				Rule:     "unknown-compiler-version",
+				Message:  fmt.Sprintf("compiler version '%s' is not a recognised release", cfg.Version),
+			})
+		}
+	}
+	return
+}
+
+func lintResourceCollisions(pkg Package, file string) (diagnostics []Diagnostic) {
+	seen := map[string]bool{}
+	for _, res := range pkg.Resources {
+		dest := res.Name
+		if seen[dest] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityError,
+				File:     file,
+				Rule:     "resource-collision",
+				Message:  fmt.Sprintf("resource '%s' has a destination that collides with another resource", dest),
+			})
+		}
+		seen[dest] = true
+	}
+	return
+}
+
+func lintMissingPlugins(pkg Package, file string) (diagnostics []Diagnostic) {
+	declared := map[string]bool{}
+	for _, dep := range pkg.GetAllDependencies() {
+		meta, err := dep.Explode()
+		if err != nil {
+			continue
+		}
+		declared[meta.Repo] = true
+	}
+
+	// Same copy-before-append reasoning as lintCompilerVersions: pkg.Runtimes
+	// must not be appended onto in place.
+	runtimes := append([]*run.Runtime(nil), pkg.Runtimes...)
+	if pkg.Runtime != nil {
+		runtimes = append(runtimes, pkg.Runtime)
+	}
+	for _, rt := range runtimes {
+		if rt == nil {
+			continue
+		}
+		for _, plugin := range rt.Plugins {
+			name := pluginDependencyName(plugin)
+			if !declared[name] {
+				diagnostics = append(diagnostics, Diagnostic{
+					Severity: SeverityWarning,
+					File:     file,
+					Rule:     "undeclared-plugin",
+					Message:  fmt.Sprintf("runtime plugin '%s' is not present in dependencies", plugin),
+				})
+			}
+		}
+	}
+	return
+}
+
+// pluginDependencyName extracts the repo name from a "user/repo" style plugin
+// reference so it can be matched against declared dependencies.
+func pluginDependencyName(plugin string) string {
+	for i := len(plugin) - 1; i >= 0; i-- {
+		if plugin[i] == '/' {
+			return plugin[i+1:]
+		}
+	}
+	return plugin
+}
@@ -0,0 +1,128 @@
+package pawnpackage
+
+import (
+	"testing"
+
+	"github.com/Southclaws/sampctl/resource"
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+func TestLintEntryOutputCollision(t *testing.T) {
+	cases := []struct {
+		name      string
+		pkg       Package
+		wantRules []string
+	}{
+		{
+			name:      "colliding entry and output",
+			pkg:       Package{Entry: "gamemode.pwn", Output: "gamemode.pwn"},
+			wantRules: []string{"entry-output-collision"},
+		},
+		{
+			name:      "distinct entry and output",
+			pkg:       Package{Entry: "gamemode.pwn", Output: "gamemode.amx"},
+			wantRules: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := lintEntryOutputCollision(c.pkg, "pawn.yaml")
+			assertRules(t, got, c.wantRules)
+		})
+	}
+}
+
+func TestLintUnpinnedDependencies(t *testing.T) {
+	cases := []struct {
+		name      string
+		deps      []versioning.DependencyString
+		wantRules []string
+	}{
+		{
+			name:      "pinned dependency is clean",
+			deps:      []versioning.DependencyString{"Southclaws/samp-stdlib@0.3.7"},
+			wantRules: nil,
+		},
+		{
+			name:      "unpinned dependency warns",
+			deps:      []versioning.DependencyString{"Southclaws/samp-stdlib"},
+			wantRules: []string{"unpinned-dependency"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pkg := Package{Dependencies: c.deps}
+			got := lintUnpinnedDependencies(pkg, "pawn.yaml")
+			assertRules(t, got, c.wantRules)
+		})
+	}
+}
+
+func TestLintDuplicateDependencies(t *testing.T) {
+	pkg := Package{
+		Dependencies: []versioning.DependencyString{
+			"Southclaws/samp-stdlib@0.3.7",
+			"Southclaws/samp-stdlib@0.3.8",
+		},
+	}
+
+	got := lintDuplicateDependencies(pkg, "pawn.yaml")
+	assertRules(t, got, []string{"duplicate-dependency"})
+}
+
+func TestLintResourceCollisions(t *testing.T) {
+	pkg := Package{
+		Resources: []resource.Resource{
+			{Name: "linux"},
+			{Name: "linux"},
+		},
+	}
+
+	got := lintResourceCollisions(pkg, "pawn.yaml")
+	assertRules(t, got, []string{"resource-collision"})
+}
+
+func TestLintIncludePaths(t *testing.T) {
+	cases := []struct {
+		name        string
+		localPath   string
+		includePath string
+		wantRules   []string
+	}{
+		{
+			name:        "no local path means nothing to check",
+			localPath:   "",
+			includePath: "include",
+			wantRules:   nil,
+		},
+		{
+			name:        "missing include path is flagged",
+			localPath:   t.TempDir(),
+			includePath: "does-not-exist",
+			wantRules:   []string{"unreachable-include-path"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pkg := Package{LocalPath: c.localPath, IncludePath: c.includePath}
+			got := lintIncludePaths(pkg, "pawn.yaml")
+			assertRules(t, got, c.wantRules)
+		})
+	}
+}
+
+func assertRules(t *testing.T, got []Diagnostic, want []string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d (%+v)", len(got), len(want), got)
+	}
+	for i, rule := range want {
+		if got[i].Rule != rule {
+			t.Errorf("diagnostic %d: got rule %q, want %q", i, got[i].Rule, rule)
+		}
+	}
+}
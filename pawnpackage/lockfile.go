@@ -0,0 +1,374 @@
+package pawnpackage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Southclaws/sampctl/util"
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+// lockfileVersion is incremented whenever the on-disk Lockfile shape changes in a
+// backwards-incompatible way.
+const lockfileVersion = 1
+
+// LockedDependency pins a single direct or transitive dependency to the exact
+// inputs that were resolved for it, so that a subsequent `Ensure` fetches
+// byte-for-byte the same thing regardless of what the dependency's upstream
+// tags or branches point to today.
+type LockedDependency struct {
+	DependencyMeta versioning.DependencyMeta `json:"dependency" yaml:"dependency"`
+	ResolvedTag    string                    `json:"resolved_tag,omitempty" yaml:"resolved_tag,omitempty"`
+	CommitSHA      string                    `json:"commit_sha" yaml:"commit_sha"`
+	SourceURL      string                    `json:"source_url" yaml:"source_url"`
+	ContentHash    string                    `json:"content_hash" yaml:"content_hash"` // sha256 of the fetched archive, hex encoded
+}
+
+// Lockfile records the exact resolution of every direct and transitive
+// dependency of a Package after an Ensure, so builds are reproducible across
+// machines. It is written as `pawn.lock` (JSON) or `pawn.lock.yaml` next to
+// the package definition, following the package's own Format.
+type Lockfile struct {
+	Version      int                `json:"version" yaml:"version"`
+	Dependencies []LockedDependency `json:"dependencies" yaml:"dependencies"`
+}
+
+// lockfileFilename returns the filename to use for a lockfile given the
+// package definition format, mirroring pawn.json/pawn.yaml naming.
+func lockfileFilename(format string) string {
+	if format == "yaml" {
+		return "pawn.lock.yaml"
+	}
+	return "pawn.lock"
+}
+
+// Get returns the locked entry for a dependency, if one exists.
+func (lf Lockfile) Get(meta versioning.DependencyMeta) (entry LockedDependency, ok bool) {
+	for _, dep := range lf.Dependencies {
+		if fmt.Sprint(dep.DependencyMeta) == fmt.Sprint(meta) {
+			return dep, true
+		}
+	}
+	return
+}
+
+// Set inserts or overwrites the locked entry for a dependency, used to
+// implement partial upgrades where only the affected entries are rewritten.
+func (lf *Lockfile) Set(entry LockedDependency) {
+	for i, dep := range lf.Dependencies {
+		if fmt.Sprint(dep.DependencyMeta) == fmt.Sprint(entry.DependencyMeta) {
+			lf.Dependencies[i] = entry
+			return
+		}
+	}
+	lf.Dependencies = append(lf.Dependencies, entry)
+}
+
+// LockfileFromDir reads a `pawn.lock`, `pawn.lock.json` or `pawn.lock.yaml`
+// from a directory, if one is present, using the first found in that order.
+// If none exists, an empty Lockfile is returned with ok set to false.
+func LockfileFromDir(dir string) (lf Lockfile, ok bool, err error) {
+	for _, candidate := range []string{
+		filepath.Join(dir, "pawn.lock"),
+		filepath.Join(dir, "pawn.lock.json"),
+		filepath.Join(dir, "pawn.lock.yaml"),
+	} {
+		if !util.Exists(candidate) {
+			continue
+		}
+
+		contents, errRead := ioutil.ReadFile(candidate)
+		if errRead != nil {
+			err = errors.Wrapf(errRead, "failed to read lockfile '%s'", candidate)
+			return
+		}
+
+		if filepath.Ext(candidate) == ".yaml" {
+			err = yaml.Unmarshal(contents, &lf)
+		} else {
+			err = json.Unmarshal(contents, &lf)
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "failed to decode lockfile '%s'", candidate)
+			return
+		}
+
+		ok = true
+		return
+	}
+
+	return
+}
+
+// WriteLockfile writes the given Lockfile to disk next to the package
+// definition, using the package's Format to decide between JSON and YAML.
+func (pkg Package) WriteLockfile(lf Lockfile) (err error) {
+	return WriteLockfileTo(pkg.LocalPath, pkg.Format, lf)
+}
+
+// WriteLockfileTo writes lf to dir, using format ("json" or "yaml") to decide
+// the encoding. This is the shared implementation behind Package.WriteLockfile,
+// also used by Workspace.EnsureAll to write a single lockfile at the
+// workspace root rather than one per member.
+func WriteLockfileTo(dir, format string, lf Lockfile) (err error) {
+	lf.Version = lockfileVersion
+
+	var contents []byte
+	if format == "yaml" {
+		contents, err = yaml.Marshal(lf)
+	} else {
+		contents, err = json.MarshalIndent(lf, "", "\t")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to encode lockfile")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, lockfileFilename(format)), contents, 0700)
+	if err != nil {
+		return errors.Wrap(err, "failed to write lockfile")
+	}
+
+	return
+}
+
+// PackageFromDirWithLock behaves like PackageFromDir but also loads the
+// package's lockfile, if present. When a lockfile exists, resolvers should
+// prefer its pinned CommitSHA/ContentHash over re-resolving a dependency's
+// Tag/Branch so that builds remain reproducible.
+func PackageFromDirWithLock(dir string) (pkg Package, lf Lockfile, err error) {
+	pkg, err = PackageFromDir(dir)
+	if err != nil {
+		return
+	}
+
+	lf, _, err = LockfileFromDir(dir)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// UpdateDependencies rewrites only the lockfile entries for the given
+// dependency names (matched against DependencyMeta.Repr-style formatting, eg.
+// "Southclaws/samp-stdlib"), leaving every other locked entry untouched. This
+// backs `sampctl package update <dep>`, as opposed to a full re-lock of every
+// dependency.
+func (lf *Lockfile) UpdateDependencies(resolved []LockedDependency, only []string) {
+	wanted := make(map[string]bool, len(only))
+	for _, name := range only {
+		wanted[name] = true
+	}
+
+	for _, entry := range resolved {
+		if len(wanted) > 0 && !wanted[fmt.Sprint(entry.DependencyMeta)] {
+			continue
+		}
+		lf.Set(entry)
+	}
+}
+
+// VerifyContentHash downloads the archive at sourceURL and confirms its
+// sha256 matches wantHash, failing loudly on mismatch so a tampered or stale
+// mirror can never be silently substituted for the locked dependency. auth,
+// if non-empty, is sent as a GitHub-style "Authorization: token" header so
+// locked dependencies on private repositories can still be re-verified.
+func VerifyContentHash(ctx context.Context, sourceURL, wantHash, auth string) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request for content hash verification")
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", "token "+auth)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch '%s' for content hash verification", sourceURL)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	gotHash, err := HashReader(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if gotHash != wantHash {
+		return errors.Errorf(
+			"content hash mismatch for '%s': locked '%s' but fetched '%s'",
+			sourceURL, wantHash, gotHash)
+	}
+
+	return
+}
+
+// HashReader returns the hex-encoded sha256 of r, used both to populate a
+// LockedDependency's ContentHash after a fresh fetch and to verify it again
+// on subsequent fetches.
+func HashReader(r io.Reader) (hash string, err error) {
+	h := sha256.New()
+	if _, err = io.Copy(h, r); err != nil {
+		return "", errors.Wrap(err, "failed to hash content")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashFile is a convenience wrapper around HashReader for archives that have
+// already been fetched to disk.
+func HashFile(path string) (hash string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open '%s' for hashing", path)
+	}
+	defer f.Close() // nolint:errcheck
+
+	return HashReader(f)
+}
+
+// EnsureDependency fetches a single dependency into destination, preferring
+// the lockfile when it already has an entry for dep: the pinned CommitSHA is
+// fetched instead of re-resolving dep's Tag/Branch (an entry recorded without
+// a CommitSHA is fetched by dep's own Tag/Branch), and the downloaded
+// archive's hash is checked against the locked ContentHash, failing loudly on
+// mismatch. When dep has no lock entry yet, it's resolved normally and the
+// result (commit, source URL, content hash) is recorded into lf so the next
+// run is reproducible. auth, if non-empty, is sent as a GitHub token with
+// every archive request, so dependencies hosted on private repositories can
+// be ensured the same way as public ones.
+func EnsureDependency(
+	ctx context.Context,
+	client *github.Client,
+	dep versioning.DependencyString,
+	lf *Lockfile,
+	destination string,
+	auth string,
+) (err error) {
+	meta, err := dep.Explode()
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse dependency '%s'", dep)
+	}
+
+	if entry, ok := lf.Get(meta); ok {
+		pinned := meta
+		if entry.CommitSHA != "" {
+			pinned.Commit = entry.CommitSHA
+			pinned.Tag = ""
+			pinned.Branch = ""
+		}
+
+		data, errFetch := fetchGitHubArchive(ctx, pinned, auth)
+		if errFetch != nil {
+			return errFetch
+		}
+
+		// Verify against the locked hash before extracting anything, so a
+		// tampered or stale mirror is rejected rather than silently unpacked.
+		gotHash, errHash := HashReader(bytes.NewReader(data))
+		if errHash != nil {
+			return errHash
+		}
+		if gotHash != entry.ContentHash {
+			return errors.Errorf(
+				"locked dependency '%s' failed verification: content hash mismatch: locked '%s' but fetched '%s'",
+				meta, entry.ContentHash, gotHash)
+		}
+
+		return extractTarGz(bytes.NewReader(data), destination)
+	}
+
+	commitSHA := meta.Commit
+	if commitSHA == "" && client != nil {
+		commitSHA = resolveCommitSHA(ctx, client, meta)
+	}
+
+	// Fetch by the resolved commit, the same ref a later locked run fetches,
+	// so the recorded content hash is that of the archive it will download.
+	fetched := meta
+	if commitSHA != "" {
+		fetched.Commit = commitSHA
+		fetched.Tag = ""
+		fetched.Branch = ""
+	}
+
+	data, err := fetchGitHubArchive(ctx, fetched, auth)
+	if err != nil {
+		return err
+	}
+
+	contentHash, err := HashReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	lf.Set(LockedDependency{
+		DependencyMeta: meta,
+		ResolvedTag:    meta.Tag,
+		CommitSHA:      commitSHA,
+		SourceURL:      githubArchiveURL(fetched),
+		ContentHash:    contentHash,
+	})
+
+	return extractTarGz(bytes.NewReader(data), destination)
+}
+
+// resolveCommitSHA looks up the commit SHA a tag or branch currently points
+// to, so a freshly-locked dependency records a concrete commit even though it
+// was declared by tag/branch. Failures are non-fatal: the lockfile entry is
+// still written, just without a CommitSHA, since the content hash alone is
+// enough to detect drift.
+func resolveCommitSHA(ctx context.Context, client *github.Client, meta versioning.DependencyMeta) string {
+	ref := meta.Tag
+	if ref == "" {
+		ref = meta.Branch
+	}
+	if ref == "" {
+		return ""
+	}
+
+	commit, _, err := client.Repositories.GetCommit(ctx, meta.User, meta.Repo, ref)
+	if err != nil || commit == nil {
+		return ""
+	}
+
+	return commit.GetSHA()
+}
+
+// EnsureAllDependencies runs EnsureDependency for every direct and
+// development dependency of pkg, fetching each into its own subdirectory of
+// vendorDir (keyed by meta.CachePath, the same Site/User/Repo-qualified
+// layout GetCachedPackage uses, so two dependencies that happen to share a
+// repo name but belong to different owners never collide), and persists lf
+// back to pkg.LocalPath once every dependency has been ensured. auth, if
+// non-empty, is forwarded to EnsureDependency for private repositories.
+func EnsureAllDependencies(ctx context.Context, client *github.Client, pkg Package, vendorDir, auth string) (err error) {
+	lf, _, err := LockfileFromDir(pkg.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range pkg.GetAllDependencies() {
+		meta, errExplode := dep.Explode()
+		if errExplode != nil {
+			return errors.Wrapf(errExplode, "failed to parse dependency '%s'", dep)
+		}
+
+		if err = EnsureDependency(ctx, client, dep, &lf, meta.CachePath(vendorDir), auth); err != nil {
+			return errors.Wrapf(err, "failed to ensure dependency '%s'", dep)
+		}
+	}
+
+	return pkg.WriteLockfile(lf)
+}
@@ -0,0 +1,87 @@
+package pawnpackage
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHashReader(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"hello", "hello", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := HashReader(bytes.NewBufferString(c.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestVerifyContentHash(t *testing.T) {
+	const body = "some archive bytes"
+	wantHash, err := HashReader(bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	t.Run("matching hash succeeds", func(t *testing.T) {
+		if err := VerifyContentHash(context.Background(), server.URL, wantHash, ""); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("mismatched hash fails loudly", func(t *testing.T) {
+		err := VerifyContentHash(context.Background(), server.URL, "not-the-real-hash", "")
+		if err == nil {
+			t.Fatal("expected an error for mismatched content hash, got nil")
+		}
+	})
+}
+
+func TestLockfileGetSet(t *testing.T) {
+	var lf Lockfile
+
+	first := LockedDependency{ContentHash: "aaa"}
+	first.DependencyMeta.User = "Southclaws"
+	first.DependencyMeta.Repo = "samp-stdlib"
+	lf.Set(first)
+
+	if len(lf.Dependencies) != 1 {
+		t.Fatalf("expected 1 dependency, got %d", len(lf.Dependencies))
+	}
+
+	updated := first
+	updated.ContentHash = "bbb"
+	lf.Set(updated)
+
+	if len(lf.Dependencies) != 1 {
+		t.Fatalf("expected Set to overwrite the existing entry, got %d entries", len(lf.Dependencies))
+	}
+
+	entry, ok := lf.Get(first.DependencyMeta)
+	if !ok {
+		t.Fatal("expected to find the locked entry")
+	}
+	if entry.ContentHash != "bbb" {
+		t.Errorf("got content hash %q, want %q", entry.ContentHash, "bbb")
+	}
+}
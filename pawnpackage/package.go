@@ -72,6 +72,7 @@ type Package struct {
 	Builds       []*build.Config               `json:"builds,omitempty" yaml:"builds,omitempty"`                     // multiple build configurations
 	IncludePath  string                        `json:"include_path,omitempty" yaml:"include_path,omitempty"`         // include path within the repository, so users don't need to specify the path explicitly
 	Resources    []resource.Resource           `json:"resources,omitempty" yaml:"resources,omitempty"`               // list of additional resources associated with the package
+	UpdatePolicy map[string]UpdateClass        `json:"update_policy,omitempty" yaml:"update_policy,omitempty"`       // per-dependency policy constraining automated `package update` runs
 }
 
 func (pkg Package) String() string {
@@ -143,6 +144,10 @@ func PackageFromDir(dir string) (pkg Package, err error) {
 
 	pkg.Format = packageDefinitionFormat
 
+	if root, ok := FindWorkspaceRoot(dir); ok {
+		pkg.Vendor = filepath.Join(root, "dependencies")
+	}
+
 	return pkg, nil
 }
 
@@ -184,19 +189,16 @@ func GetCachedPackage(meta versioning.DependencyMeta, cacheDir string) (pkg Pack
 }
 
 // GetRemotePackage attempts to get a package definition for the given dependency meta.
-// It first checks the the sampctl central repository, if that fails it falls back to using the
-// repository for the package itself. This means upstream changes to plugins can be first staged in
+// It dispatches to the Resolver registered for meta's Site (see RegisterResolver), which for
+// plain GitHub dependencies checks the sampctl central repository first and falls back to using
+// the repository for the package itself, so upstream changes to plugins can be first staged in
 // the official central repository before being pulled to the package specific repository.
 func GetRemotePackage(
 	ctx context.Context,
 	client *github.Client,
 	meta versioning.DependencyMeta,
 ) (pkg Package, err error) {
-	pkg, err = PackageFromOfficialRepo(ctx, client, meta)
-	if err != nil {
-		return PackageFromRepo(ctx, client, meta)
-	}
-	return
+	return ResolvePackage(ctx, client, meta)
 }
 
 // PackageFromRepo attempts to get a package from the given package definition's public repo
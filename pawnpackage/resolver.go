@@ -0,0 +1,281 @@
+package pawnpackage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/sampctl/util"
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+// Resolver describes a package source: something capable of locating a
+// package definition for a DependencyMeta and fetching the associated source
+// tree to a destination directory. Built-in resolvers cover GitHub, GitLab,
+// Bitbucket, the sampctl central repo, generic Git remotes and local
+// filesystem mirrors, but third parties can register their own via
+// RegisterResolver to support private mirrors or corporate Git servers.
+type Resolver interface {
+	// Resolve fetches the package definition (pawn.json/pawn.yaml) for meta
+	// without fetching the full source tree.
+	Resolve(ctx context.Context, client *github.Client, meta versioning.DependencyMeta) (pkg Package, err error)
+	// Fetch retrieves the full source tree for meta into destination. auth,
+	// if non-empty, is a token or credential used to authenticate against
+	// the resolver's Git host, so private repositories and corporate Git
+	// servers can be fetched the same way as public ones.
+	Fetch(ctx context.Context, meta versioning.DependencyMeta, destination, auth string) (err error)
+}
+
+var (
+	resolversMu sync.Mutex
+	resolvers   = map[string]Resolver{}
+)
+
+// RegisterResolver associates a Resolver implementation with a
+// DependencyMeta.Site scheme, such as "github.com", "gitlab.com",
+// "bitbucket.org", "git" or "local". Registering a scheme that is already registered overwrites the
+// previous resolver, which lets a consumer override a built-in resolver with
+// their own implementation.
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = r
+}
+
+// GetResolver returns the Resolver registered for scheme, if any.
+func GetResolver(scheme string) (r Resolver, ok bool) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	r, ok = resolvers[scheme]
+	return
+}
+
+// Resolver scheme names, matched against a dependency's Site field.
+const (
+	SiteGitHub    = "github.com"
+	SiteGitLab    = "gitlab.com"
+	SiteBitbucket = "bitbucket.org"
+	SiteGit       = "git"
+	SiteLocal     = "local"
+)
+
+func init() {
+	RegisterResolver(SiteGitHub, githubResolver{})
+	RegisterResolver(SiteGitLab, gitlabResolver{})
+	RegisterResolver(SiteBitbucket, bitbucketResolver{})
+	RegisterResolver(SiteGit, gitResolver{})
+	RegisterResolver(SiteLocal, localResolver{})
+}
+
+// resolverScheme maps a dependency's Site field to a registered resolver
+// scheme, defaulting to GitHub since the vast majority of existing
+// dependencies have no Site set at all (it predates this registry).
+func resolverScheme(meta versioning.DependencyMeta) string {
+	if meta.Site == "" {
+		return SiteGitHub
+	}
+	return meta.Site
+}
+
+// ResolvePackage dispatches to the Resolver registered for meta's Site,
+// falling back to the GitHub resolver if none is registered. This is the
+// single entry point package resolution should go through so that
+// RegisterResolver actually takes effect.
+func ResolvePackage(ctx context.Context, client *github.Client, meta versioning.DependencyMeta) (pkg Package, err error) {
+	r, ok := GetResolver(resolverScheme(meta))
+	if !ok {
+		r = githubResolver{}
+	}
+	return r.Resolve(ctx, client, meta)
+}
+
+// FetchPackage dispatches to the Resolver registered for meta's Site to
+// retrieve the full source tree into destination, forwarding auth so
+// resolvers that clone or download over HTTP(S) can authenticate against
+// private repositories and corporate Git servers.
+func FetchPackage(ctx context.Context, meta versioning.DependencyMeta, destination, auth string) (err error) {
+	r, ok := GetResolver(resolverScheme(meta))
+	if !ok {
+		r = githubResolver{}
+	}
+	return r.Fetch(ctx, meta, destination, auth)
+}
+
+// githubResolver resolves packages hosted on github.com, this is the
+// historical, default resolution path: check the sampctl central repo first,
+// falling back to the package's own repo.
+type githubResolver struct{}
+
+func (githubResolver) Resolve(ctx context.Context, client *github.Client, meta versioning.DependencyMeta) (pkg Package, err error) {
+	pkg, err = PackageFromOfficialRepo(ctx, client, meta)
+	if err != nil {
+		return PackageFromRepo(ctx, client, meta)
+	}
+	return
+}
+
+// Fetch downloads the tarball for meta's pinned commit/tag/branch (falling
+// back to the repository's default branch) from codeload.github.com and
+// extracts it into destination, stripping the single top-level directory
+// that GitHub's archives wrap everything in. auth, if non-empty, is sent as
+// a GitHub token so private repositories can be fetched too.
+func (githubResolver) Fetch(ctx context.Context, meta versioning.DependencyMeta, destination, auth string) (err error) {
+	data, err := fetchGitHubArchive(ctx, meta, auth)
+	if err != nil {
+		return err
+	}
+	return extractTarGz(bytes.NewReader(data), destination)
+}
+
+// githubArchiveRef picks the most specific pin available on meta, falling
+// back to the repository's default branch.
+func githubArchiveRef(meta versioning.DependencyMeta) string {
+	switch {
+	case meta.Commit != "":
+		return meta.Commit
+	case meta.Tag != "":
+		return meta.Tag
+	case meta.Branch != "":
+		return meta.Branch
+	default:
+		return "HEAD"
+	}
+}
+
+// githubArchiveURL returns the codeload.github.com tarball URL for meta's
+// pinned ref, shared between githubResolver.Fetch and the lockfile-aware
+// ensure path so both hash and extract the exact same bytes.
+func githubArchiveURL(meta versioning.DependencyMeta) string {
+	return fmt.Sprintf("https://codeload.github.com/%s/%s/tar.gz/%s", meta.User, meta.Repo, githubArchiveRef(meta))
+}
+
+// fetchGitHubArchive downloads meta's pinned tarball and returns its raw
+// bytes, so callers can both extract it and compute its content hash without
+// downloading twice. auth, if non-empty, is sent as a GitHub token so
+// private repositories can be downloaded the same way as public ones.
+func fetchGitHubArchive(ctx context.Context, meta versioning.DependencyMeta, auth string) (data []byte, err error) {
+	resp, err := httpGetAuth(ctx, githubArchiveURL(meta), auth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download archive for '%s'", meta)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.StatusCode != 200 {
+		return nil, errors.Errorf("failed to download archive for '%s': status %d", meta, resp.StatusCode)
+	}
+
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read archive for '%s'", meta)
+	}
+
+	return data, nil
+}
+
+// gitlabResolver resolves packages hosted on gitlab.com, using the same
+// pawn.json/pawn.yaml discovery convention as GitHub but against GitLab's raw
+// file API. Every URL it builds points at gitlab.com; self-hosted GitLab
+// instances are not reached by this resolver.
+type gitlabResolver struct{}
+
+func (gitlabResolver) Resolve(ctx context.Context, client *github.Client, meta versioning.DependencyMeta) (pkg Package, err error) {
+	for _, file := range []string{"pawn.json", "pawn.yaml"} {
+		resp, errGet := httpGet(ctx, fmt.Sprintf(
+			"https://gitlab.com/%s/%s/-/raw/master/%s", meta.User, meta.Repo, file))
+		if errGet != nil {
+			err = errGet
+			return
+		}
+		defer resp.Body.Close() // nolint:errcheck
+
+		if resp.StatusCode != 200 {
+			continue
+		}
+		if file == "pawn.json" {
+			return packageFromJSONResponse(resp, meta)
+		}
+		return packageFromYAMLResponse(resp, meta)
+	}
+	return pkg, errors.Errorf("package '%s' does not exist on GitLab", meta)
+}
+
+func (gitlabResolver) Fetch(ctx context.Context, meta versioning.DependencyMeta, destination, auth string) (err error) {
+	return gitCloneMeta(ctx, fmt.Sprintf("https://gitlab.com/%s/%s.git", meta.User, meta.Repo), meta, destination, auth)
+}
+
+// bitbucketResolver resolves packages hosted on bitbucket.org.
+type bitbucketResolver struct{}
+
+func (bitbucketResolver) Resolve(ctx context.Context, client *github.Client, meta versioning.DependencyMeta) (pkg Package, err error) {
+	for _, file := range []string{"pawn.json", "pawn.yaml"} {
+		resp, errGet := httpGet(ctx, fmt.Sprintf(
+			"https://bitbucket.org/%s/%s/raw/master/%s", meta.User, meta.Repo, file))
+		if errGet != nil {
+			err = errGet
+			return
+		}
+		defer resp.Body.Close() // nolint:errcheck
+
+		if resp.StatusCode != 200 {
+			continue
+		}
+		if file == "pawn.json" {
+			return packageFromJSONResponse(resp, meta)
+		}
+		return packageFromYAMLResponse(resp, meta)
+	}
+	return pkg, errors.Errorf("package '%s' does not exist on Bitbucket", meta)
+}
+
+func (bitbucketResolver) Fetch(ctx context.Context, meta versioning.DependencyMeta, destination, auth string) (err error) {
+	return gitCloneMeta(ctx, fmt.Sprintf("https://bitbucket.org/%s/%s.git", meta.User, meta.Repo), meta, destination, auth)
+}
+
+// gitResolver resolves packages from an arbitrary Git remote URL, stored in
+// meta.Repo, via go-git. This is the escape hatch for corporate Git servers
+// that don't speak the GitHub/GitLab/Bitbucket raw-file APIs.
+type gitResolver struct{}
+
+func (gitResolver) Resolve(ctx context.Context, client *github.Client, meta versioning.DependencyMeta) (pkg Package, err error) {
+	dir, err := ioutilTempDir("sampctl-git-resolve")
+	if err != nil {
+		return
+	}
+	defer removeAll(dir) // nolint:errcheck
+
+	if err = gitCloneMeta(ctx, meta.Repo, meta, dir, ""); err != nil {
+		return
+	}
+
+	return PackageFromDir(dir)
+}
+
+func (gitResolver) Fetch(ctx context.Context, meta versioning.DependencyMeta, destination, auth string) (err error) {
+	return gitCloneMeta(ctx, meta.Repo, meta, destination, auth)
+}
+
+// localResolver resolves packages from a path on the local filesystem, stored
+// in meta.Repo, used for vendored offline caches or monorepo mirrors.
+type localResolver struct{}
+
+func (localResolver) Resolve(ctx context.Context, client *github.Client, meta versioning.DependencyMeta) (pkg Package, err error) {
+	if !util.Exists(meta.Repo) {
+		return pkg, errors.Errorf("local mirror '%s' does not exist", meta.Repo)
+	}
+	return PackageFromDir(meta.Repo)
+}
+
+// Fetch copies the local mirror at meta.Repo into destination. auth is
+// accepted to satisfy the Resolver interface but is meaningless for a
+// filesystem path, so it's ignored.
+func (localResolver) Fetch(ctx context.Context, meta versioning.DependencyMeta, destination, auth string) (err error) {
+	if !util.Exists(meta.Repo) {
+		return errors.Errorf("local mirror '%s' does not exist", meta.Repo)
+	}
+	return util.CopyDir(meta.Repo, destination)
+}
@@ -0,0 +1,176 @@
+package pawnpackage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	gittransport "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+// httpGet is a context-aware wrapper around http.Get, used by the non-GitHub
+// resolvers to fetch raw package definition files.
+func httpGet(ctx context.Context, url string) (resp *http.Response, err error) {
+	return httpGetAuth(ctx, url, "")
+}
+
+// httpGetAuth behaves like httpGet but sends auth in a GitHub-style
+// "Authorization: token" header when non-empty, letting resolvers fetch from
+// private repositories.
+func httpGetAuth(ctx context.Context, url, auth string) (resp *http.Response, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build request for '%s'", url)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", "token "+auth)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// gitCloneMeta clones url into destination, checking out meta's pinned
+// Commit, Tag or Branch (in that order of preference) so resolvers other than
+// the GitHub archive downloader still respect a dependency's version pin.
+// auth, if non-empty, is used as an HTTP basic auth password (with a
+// placeholder username, following the convention GitHub/GitLab/Bitbucket
+// personal access tokens expect) so private remotes and corporate Git
+// servers can be cloned too.
+func gitCloneMeta(ctx context.Context, url string, meta versioning.DependencyMeta, destination, auth string) (err error) {
+	opts := &git.CloneOptions{URL: url}
+	if auth != "" {
+		opts.Auth = &gittransport.BasicAuth{Username: "sampctl", Password: auth}
+	}
+
+	repo, err := git.PlainCloneContext(ctx, destination, false, opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to clone '%s'", url)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return errors.Wrap(err, "failed to open worktree")
+	}
+
+	switch {
+	case meta.Commit != "":
+		err = wt.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(meta.Commit)})
+	case meta.Tag != "":
+		err = wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewTagReferenceName(meta.Tag)})
+	case meta.Branch != "":
+		err = wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(meta.Branch)})
+	default:
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to check out pinned revision for '%s'", meta)
+	}
+
+	return nil
+}
+
+func ioutilTempDir(prefix string) (string, error) {
+	return ioutil.TempDir("", prefix)
+}
+
+func removeAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+// extractTarGz extracts a gzipped tarball read from r into destination,
+// stripping the first path component of every entry - GitHub (and most Git
+// hosts) wrap an archive's contents in a single "<repo>-<ref>/" directory
+// that callers don't want to see on disk.
+func extractTarGz(r io.Reader, destination string) (err error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to open archive as gzip")
+	}
+	defer gzr.Close() // nolint:errcheck
+
+	if err = os.MkdirAll(destination, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create destination '%s'", destination)
+	}
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, errNext := tr.Next()
+		if errNext == io.EOF {
+			return nil
+		}
+		if errNext != nil {
+			return errors.Wrap(errNext, "failed to read archive")
+		}
+
+		name := stripFirstPathComponent(header.Name)
+		if name == "" {
+			continue
+		}
+
+		if header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
+			return errors.Errorf("archive entry '%s' is a link, which is not allowed", header.Name)
+		}
+
+		target, err := safeJoin(destination, name)
+		if err != nil {
+			return errors.Wrapf(err, "archive entry '%s' escapes destination", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return errors.Wrapf(err, "failed to create directory '%s'", target)
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return errors.Wrapf(err, "failed to create directory for '%s'", target)
+			}
+			f, errCreate := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode))
+			if errCreate != nil {
+				return errors.Wrapf(errCreate, "failed to create '%s'", target)
+			}
+			_, err = io.Copy(f, tr) // nolint:gosec
+			f.Close()               // nolint:errcheck
+			if err != nil {
+				return errors.Wrapf(err, "failed to write '%s'", target)
+			}
+		}
+	}
+}
+
+// safeJoin joins name onto destination and guarantees the result stays
+// within destination, rejecting archive entries (eg. "../../etc/passwd")
+// that would otherwise let a tampered or malicious archive write outside the
+// extraction directory (a "tar-slip").
+func safeJoin(destination, name string) (target string, err error) {
+	target = filepath.Join(destination, name)
+
+	rel, err := filepath.Rel(destination, target)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve relative path")
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("path traversal in archive entry '%s'", name)
+	}
+
+	return target, nil
+}
+
+func stripFirstPathComponent(name string) string {
+	name = filepath.ToSlash(name)
+	i := strings.Index(name, "/")
+	if i < 0 {
+		return ""
+	}
+	return name[i+1:]
+}
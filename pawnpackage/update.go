@@ -0,0 +1,232 @@
+package pawnpackage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/semver"
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+// UpdateClass classifies a dependency update by the magnitude of the semver
+// jump between the locked version and the newest tag that satisfies it.
+type UpdateClass string
+
+// Update classes, ordered from most to least permissive. UpdateNone means a
+// dependency has no update policy configured and so should never be bumped
+// automatically.
+const (
+	UpdateMajor UpdateClass = "major"
+	UpdateMinor UpdateClass = "minor"
+	UpdatePatch UpdateClass = "patch"
+	UpdateNone  UpdateClass = "none"
+)
+
+// allows reports whether an update of class want is permitted under policy.
+func (policy UpdateClass) allows(want UpdateClass) bool {
+	switch policy {
+	case UpdateMajor:
+		return want == UpdateMajor || want == UpdateMinor || want == UpdatePatch
+	case UpdateMinor:
+		return want == UpdateMinor || want == UpdatePatch
+	case UpdatePatch:
+		return want == UpdatePatch
+	default:
+		return false
+	}
+}
+
+// DependencyUpdate describes a single dependency whose upstream tags have
+// advanced beyond the version currently locked/declared.
+type DependencyUpdate struct {
+	Dependency     versioning.DependencyMeta `json:"dependency"`
+	CurrentVersion string                    `json:"current_version"`
+	LatestVersion  string                    `json:"latest_version"`
+	Class          UpdateClass               `json:"class"`
+	Allowed        bool                      `json:"allowed"` // whether Package.UpdatePolicy permits applying this update
+	Changelog      []string                  `json:"changelog,omitempty"`
+}
+
+// UpdateReport is the result of checking a package's dependencies against
+// their upstream repositories for newer tags, suitable for both human
+// printing and `--format json` CI consumption.
+type UpdateReport struct {
+	Updates []DependencyUpdate `json:"updates"`
+}
+
+// Outdated returns only the updates that are not already the latest.
+func (r UpdateReport) Outdated() (updates []DependencyUpdate) {
+	for _, u := range r.Updates {
+		if u.CurrentVersion != u.LatestVersion {
+			updates = append(updates, u)
+		}
+	}
+	return
+}
+
+// CheckUpdates queries GitHub for the newest semver tag of every dependency
+// and classifies the jump from the currently locked/declared version as
+// major, minor or patch. Dependencies pinned to a branch or commit (rather
+// than a tag) are skipped since there's no semver to compare against.
+func (pkg Package) CheckUpdates(ctx context.Context, client *github.Client) (report UpdateReport, err error) {
+	for _, depString := range pkg.GetAllDependencies() {
+		dep, errExplode := depString.Explode()
+		if errExplode != nil {
+			err = errors.Wrapf(errExplode, "failed to parse dependency '%s'", depString)
+			return
+		}
+		if dep.Tag == "" {
+			continue // branch/commit pins have no semver to advance
+		}
+
+		update, errCheck := checkDependencyUpdate(ctx, client, dep)
+		if errCheck != nil {
+			err = errors.Wrapf(errCheck, "failed to check updates for '%s'", dep)
+			return
+		}
+
+		update.Allowed = pkg.updateAllowed(dep, update.Class)
+		report.Updates = append(report.Updates, update)
+	}
+
+	return
+}
+
+// updateAllowed looks up the configured UpdatePolicy for dep, defaulting to
+// UpdateNone (no automatic updates) when unspecified.
+func (pkg Package) updateAllowed(dep versioning.DependencyMeta, class UpdateClass) bool {
+	policy, ok := pkg.UpdatePolicy[fmt.Sprintf("%s/%s", dep.User, dep.Repo)]
+	if !ok {
+		policy = UpdateNone
+	}
+	return policy.allows(class)
+}
+
+func checkDependencyUpdate(
+	ctx context.Context,
+	client *github.Client,
+	dep versioning.DependencyMeta,
+) (update DependencyUpdate, err error) {
+	update.Dependency = dep
+	update.CurrentVersion = dep.Tag
+
+	current, err := semver.NewVersion(dep.Tag)
+	if err != nil {
+		return update, errors.Wrapf(err, "current tag '%s' is not valid semver", dep.Tag)
+	}
+
+	latestTag, latest, err := latestSemverTag(ctx, client, dep.User, dep.Repo)
+	if err != nil {
+		return update, err
+	}
+	if latest == nil || !latest.GreaterThan(current) {
+		update.LatestVersion = update.CurrentVersion
+		update.Class = UpdateNone
+		return update, nil
+	}
+
+	update.LatestVersion = latestTag
+	update.Class = classifyUpdate(current, latest)
+
+	update.Changelog, err = aggregateChangelog(ctx, client, dep.User, dep.Repo, dep.Tag, latestTag)
+	if err != nil {
+		return update, err
+	}
+
+	return update, nil
+}
+
+// LatestSemverTag returns the newest tag of user/repo that parses as valid
+// semver, or an empty string if the repository has no semver tags at all.
+// Unlike trusting ListTags' response order (which GitHub does not guarantee
+// to be recency- or version-sorted), every tag is parsed and compared.
+func LatestSemverTag(ctx context.Context, client *github.Client, user, repo string) (tag string, err error) {
+	tag, _, err = latestSemverTag(ctx, client, user, repo)
+	return
+}
+
+func latestSemverTag(
+	ctx context.Context,
+	client *github.Client,
+	user, repo string,
+) (latestTag string, latest *semver.Version, err error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		tags, resp, errList := client.Repositories.ListTags(ctx, user, repo, opts)
+		if errList != nil {
+			return "", nil, errors.Wrap(errList, "failed to list tags")
+		}
+
+		for _, tag := range tags {
+			v, errParse := semver.NewVersion(tag.GetName())
+			if errParse != nil {
+				continue // non-semver tags (eg. release notes tags) are ignored
+			}
+			if latest == nil || v.GreaterThan(latest) {
+				latest = v
+				latestTag = tag.GetName()
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return latestTag, latest, nil
+}
+
+// classifyUpdate determines whether the jump from current to latest is a
+// major, minor or patch bump.
+func classifyUpdate(current, latest *semver.Version) UpdateClass {
+	switch {
+	case latest.Major() != current.Major():
+		return UpdateMajor
+	case latest.Minor() != current.Minor():
+		return UpdateMinor
+	default:
+		return UpdatePatch
+	}
+}
+
+// aggregateChangelog fetches the commit messages between two tags via the
+// GitHub compare API and returns them newest-first, providing a dependabot
+// style changelog for the update.
+func aggregateChangelog(
+	ctx context.Context,
+	client *github.Client,
+	user, repo, from, to string,
+) (messages []string, err error) {
+	comparison, _, err := client.Repositories.CompareCommits(ctx, user, repo, from, to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compare '%s...%s'", from, to)
+	}
+
+	for _, commit := range comparison.Commits {
+		if commit.Commit == nil || commit.Commit.Message == nil {
+			continue
+		}
+		messages = append(messages, firstLine(*commit.Commit.Message))
+	}
+
+	// GitHub returns commits oldest-first; reverse so the changelog reads
+	// newest-first, matching dependabot-style update summaries.
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return
+}
+
+func firstLine(s string) string {
+	for i, r := range s {
+		if r == '\n' {
+			return s[:i]
+		}
+	}
+	return s
+}
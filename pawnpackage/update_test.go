@@ -0,0 +1,66 @@
+package pawnpackage
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver"
+)
+
+func TestClassifyUpdate(t *testing.T) {
+	cases := []struct {
+		name    string
+		current string
+		latest  string
+		want    UpdateClass
+	}{
+		{"major bump", "1.2.3", "2.0.0", UpdateMajor},
+		{"minor bump", "1.2.3", "1.3.0", UpdateMinor},
+		{"patch bump", "1.2.3", "1.2.4", UpdatePatch},
+		{"no change", "1.2.3", "1.2.3", UpdatePatch},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			current, err := semver.NewVersion(c.current)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			latest, err := semver.NewVersion(c.latest)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := classifyUpdate(current, latest)
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestUpdateClassAllows(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy UpdateClass
+		want   UpdateClass
+		allow  bool
+	}{
+		{"major policy allows major", UpdateMajor, UpdateMajor, true},
+		{"major policy allows minor", UpdateMajor, UpdateMinor, true},
+		{"major policy allows patch", UpdateMajor, UpdatePatch, true},
+		{"minor policy allows minor", UpdateMinor, UpdateMinor, true},
+		{"minor policy allows patch", UpdateMinor, UpdatePatch, true},
+		{"minor policy rejects major", UpdateMinor, UpdateMajor, false},
+		{"patch policy allows patch", UpdatePatch, UpdatePatch, true},
+		{"patch policy rejects minor", UpdatePatch, UpdateMinor, false},
+		{"none policy rejects everything", UpdateNone, UpdatePatch, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.policy.allows(c.want); got != c.allow {
+				t.Errorf("got %v, want %v", got, c.allow)
+			}
+		})
+	}
+}
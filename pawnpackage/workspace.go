@@ -0,0 +1,286 @@
+package pawnpackage
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Southclaws/sampctl/print"
+	"github.com/Southclaws/sampctl/util"
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+// workspaceFilename is the name of the file that marks the root of a
+// workspace, analogous to how pawn.json/pawn.yaml marks a package.
+const workspaceFilename = "pawn.workspace.yaml"
+
+// Workspace groups several member Packages under a single root, so a
+// monorepo of libraries and gamemodes can share one Vendor directory and
+// resolve dependencies between members by path instead of re-downloading
+// them.
+type Workspace struct {
+	RootPath string `json:"-" yaml:"-"`
+
+	// Members is a list of globs (eg. "libs/*", "gamemodes/*") resolved
+	// relative to RootPath, each match expected to contain its own
+	// pawn.json/pawn.yaml.
+	Members []string `json:"members" yaml:"members"`
+
+	// Dependencies are shared across every member package, resolved once
+	// into Vendor rather than once per member.
+	Dependencies []versioning.DependencyString `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+
+	// Vendor is the single dependencies directory shared by every member.
+	Vendor string `json:"-" yaml:"-"`
+
+	// Packages is populated by WorkspaceFromDir by expanding Members.
+	Packages []Package `json:"-" yaml:"-"`
+}
+
+// WorkspaceFromDir reads a pawn.workspace.yaml from dir and expands its
+// Members globs into loaded Packages.
+func WorkspaceFromDir(dir string) (ws Workspace, err error) {
+	path := filepath.Join(dir, workspaceFilename)
+	if !util.Exists(path) {
+		return ws, errors.Errorf("no %s in '%s'", workspaceFilename, dir)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ws, errors.Wrapf(err, "failed to read '%s'", path)
+	}
+
+	if err = yaml.Unmarshal(contents, &ws); err != nil {
+		return ws, errors.Wrapf(err, "failed to decode '%s'", path)
+	}
+
+	ws.RootPath = dir
+	ws.Vendor = filepath.Join(dir, "dependencies")
+
+	for _, pattern := range ws.Members {
+		matches, errGlob := filepath.Glob(filepath.Join(dir, pattern))
+		if errGlob != nil {
+			return ws, errors.Wrapf(errGlob, "failed to expand member glob '%s'", pattern)
+		}
+		for _, match := range matches {
+			member, errLoad := PackageFromDir(match)
+			if errLoad != nil {
+				return ws, errors.Wrapf(errLoad, "failed to load member package '%s'", match)
+			}
+			member.Parent = true
+			member.Vendor = ws.Vendor
+			ws.Packages = append(ws.Packages, member)
+		}
+	}
+
+	return ws, nil
+}
+
+// FindWorkspaceRoot walks up from dir looking for an enclosing
+// pawn.workspace.yaml, returning the directory that contains it. This lets a
+// member package's PackageFromDir discover the workspace it belongs to
+// without the caller needing to know the monorepo's layout up front.
+func FindWorkspaceRoot(dir string) (root string, ok bool) {
+	current := dir
+	for {
+		if util.Exists(filepath.Join(current, workspaceFilename)) {
+			return current, true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", false
+		}
+		current = parent
+	}
+}
+
+// memberAt returns the loaded member package whose User and Repo match meta,
+// if any, used to resolve intra-workspace dependencies by path rather than
+// re-downloading them.
+func (ws Workspace) memberAt(meta versioning.DependencyMeta) (pkg Package, ok bool) {
+	for _, member := range ws.Packages {
+		if member.User == meta.User && member.Repo == meta.Repo {
+			return member, true
+		}
+	}
+	return
+}
+
+// Graph returns the workspace's intra-member dependency graph: for each
+// member, the members it directly depends on. Dependencies on packages
+// outside the workspace are omitted since they don't participate in build
+// ordering. Members are keyed by LocalPath rather than their
+// DependencyMeta.String(), since members are discovered by glob and
+// typically have no Site/User/Repo set at all - keying by the meta would
+// collide every member onto the same empty key.
+func (ws Workspace) Graph() (graph map[string][]string, err error) {
+	graph = make(map[string][]string, len(ws.Packages))
+
+	for _, member := range ws.Packages {
+		key := member.LocalPath
+		graph[key] = nil
+
+		for _, depString := range member.GetAllDependencies() {
+			dep, errExplode := depString.Explode()
+			if errExplode != nil {
+				return nil, errors.Wrapf(errExplode, "failed to parse dependency '%s' of '%s'", depString, key)
+			}
+			if other, ok := ws.memberAt(dep); ok {
+				graph[key] = append(graph[key], other.LocalPath)
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+// BuildOrder topologically sorts the workspace's member dependency graph so
+// that a package is only built after everything it depends on, detecting
+// cycles rather than looping forever.
+func (ws Workspace) BuildOrder() (order []string, err error) {
+	graph, err := ws.Graph()
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(graph))
+
+	var visit func(node string) error
+	visit = func(node string) error {
+		switch state[node] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("dependency cycle detected at '%s'", node)
+		}
+
+		state[node] = visiting
+		for _, dep := range graph[node] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[node] = visited
+		order = append(order, node)
+
+		return nil
+	}
+
+	for node := range graph {
+		if err := visit(node); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// byLocalPath indexes the workspace's members by LocalPath, matching the
+// keys produced by Graph/BuildOrder.
+func (ws Workspace) byLocalPath() map[string]Package {
+	byKey := make(map[string]Package, len(ws.Packages))
+	for _, member := range ws.Packages {
+		byKey[member.LocalPath] = member
+	}
+	return byKey
+}
+
+// EnsureAll resolves ws.Dependencies (the set shared by every member) and
+// then each member's own dependencies into the shared Vendor directory, in
+// dependency order, skipping any dependency that resolves to another member
+// of the same workspace since those are always used from their local path
+// rather than re-downloaded. Every dependency is fetched into
+// meta.CachePath(ws.Vendor) rather than vendorDir/Repo, so two dependencies
+// that share a repo name but belong to different owners don't clobber each
+// other. auth, if non-empty, is forwarded to EnsureDependency so workspaces
+// with dependencies on private or corporate Git servers can still resolve
+// them. A single lockfile at the workspace root is shared across every
+// member and written back once after everything has been ensured.
+func (ws Workspace) EnsureAll(ctx context.Context, client *github.Client, auth string) (err error) {
+	order, err := ws.BuildOrder()
+	if err != nil {
+		return err
+	}
+	byKey := ws.byLocalPath()
+
+	lf, _, err := LockfileFromDir(ws.RootPath)
+	if err != nil {
+		return err
+	}
+
+	for _, depString := range ws.Dependencies {
+		dep, errExplode := depString.Explode()
+		if errExplode != nil {
+			return errors.Wrapf(errExplode, "failed to parse workspace dependency '%s'", depString)
+		}
+
+		if err = EnsureDependency(ctx, client, depString, &lf, dep.CachePath(ws.Vendor), auth); err != nil {
+			return errors.Wrapf(err, "failed to ensure workspace dependency '%s'", depString)
+		}
+	}
+
+	for _, key := range order {
+		member, ok := byKey[key]
+		if !ok {
+			continue
+		}
+
+		print.Info("ensuring workspace member", member.LocalPath)
+
+		for _, depString := range member.GetAllDependencies() {
+			dep, errExplode := depString.Explode()
+			if errExplode != nil {
+				return errors.Wrapf(errExplode, "failed to parse dependency '%s' of '%s'", depString, member.LocalPath)
+			}
+
+			if _, ok := ws.memberAt(dep); ok {
+				print.Verb("skipping", dep, "- resolved to workspace member", member.LocalPath)
+				continue
+			}
+
+			if err = EnsureDependency(ctx, client, depString, &lf, dep.CachePath(ws.Vendor), auth); err != nil {
+				return errors.Wrapf(err, "failed to ensure '%s' for workspace member '%s'", depString, member.LocalPath)
+			}
+		}
+	}
+
+	return WriteLockfileTo(ws.RootPath, "yaml", lf)
+}
+
+// BuildAll builds every member package in dependency order, so a change to a
+// shared include triggers rebuilds of everything that depends on it. The
+// actual compilation of a member is delegated to the build callback, which
+// the caller supplies (typically invoking the Pawn compiler via the build
+// package); BuildAll's own job is solely to get the order right and stop at
+// the first failure.
+func (ws Workspace) BuildAll(ctx context.Context, name string, build func(ctx context.Context, member Package, name string) error) (err error) {
+	order, err := ws.BuildOrder()
+	if err != nil {
+		return err
+	}
+	byKey := ws.byLocalPath()
+
+	for _, key := range order {
+		member, ok := byKey[key]
+		if !ok {
+			continue
+		}
+
+		print.Info("building workspace member", member.LocalPath, "using build config", name)
+
+		if err = build(ctx, member, name); err != nil {
+			return errors.Wrapf(err, "failed to build workspace member '%s'", member.LocalPath)
+		}
+	}
+
+	return nil
+}
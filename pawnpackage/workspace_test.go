@@ -0,0 +1,99 @@
+package pawnpackage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Southclaws/sampctl/versioning"
+)
+
+func newMember(localPath, user, repo string, deps ...versioning.DependencyString) Package {
+	pkg := Package{LocalPath: localPath}
+	pkg.User = user
+	pkg.Repo = repo
+	pkg.Dependencies = deps
+	return pkg
+}
+
+func TestWorkspaceBuildOrder(t *testing.T) {
+	// gamemode depends on lib, lib has no workspace-local dependencies.
+	ws := Workspace{
+		Packages: []Package{
+			newMember("/ws/gamemodes/main", "acme", "gamemode", "acme/lib@1.0.0"),
+			newMember("/ws/libs/lib", "acme", "lib"),
+		},
+	}
+
+	order, err := ws.BuildOrder()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	index := make(map[string]int, len(order))
+	for i, key := range order {
+		index[key] = i
+	}
+
+	if index["/ws/libs/lib"] >= index["/ws/gamemodes/main"] {
+		t.Errorf("expected lib to build before gamemode, got order %v", order)
+	}
+}
+
+func TestWorkspaceBuildOrderDetectsCycle(t *testing.T) {
+	ws := Workspace{
+		Packages: []Package{
+			newMember("/ws/libs/a", "acme", "a", "acme/b@1.0.0"),
+			newMember("/ws/libs/b", "acme", "b", "acme/a@1.0.0"),
+		},
+	}
+
+	_, err := ws.BuildOrder()
+	if err == nil {
+		t.Fatal("expected a dependency cycle error, got nil")
+	}
+}
+
+func TestWorkspaceGraphKeysByLocalPath(t *testing.T) {
+	// Members with no Site/User/Repo set (the common case for local-only
+	// workspace packages) must not collide onto the same graph key.
+	ws := Workspace{
+		Packages: []Package{
+			newMember("/ws/gamemodes/one", "", ""),
+			newMember("/ws/gamemodes/two", "", ""),
+		},
+	}
+
+	graph, err := ws.Graph()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(graph) != 2 {
+		t.Fatalf("expected 2 distinct graph nodes, got %d: %+v", len(graph), graph)
+	}
+}
+
+func TestWorkspaceBuildAllRespectsOrder(t *testing.T) {
+	ws := Workspace{
+		Packages: []Package{
+			newMember("/ws/gamemodes/main", "acme", "gamemode", "acme/lib@1.0.0"),
+			newMember("/ws/libs/lib", "acme", "lib"),
+		},
+	}
+
+	var built []string
+	err := ws.BuildAll(context.Background(), "", func(ctx context.Context, member Package, name string) error {
+		built = append(built, member.LocalPath)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(built) != 2 {
+		t.Fatalf("expected 2 members to be built, got %d: %v", len(built), built)
+	}
+	if built[0] != "/ws/libs/lib" || built[1] != "/ws/gamemodes/main" {
+		t.Errorf("expected lib to build before gamemode, got %v", built)
+	}
+}